@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"runtime/cgo"
 	"strings"
+	"sync"
 	"unsafe"
 
 	"github.com/ebitengine/purego"
@@ -51,6 +52,26 @@ func (err Error) Error() string {
 	return C.GoString(C.athcon_status_code_to_string(C.enum_athcon_status_code(err.Code)))
 }
 
+// HostError wraps an error returned by a HostContext method (e.g. a storage
+// backend hitting DB corruption or an I/O fault). athcon_execute itself has
+// no way to observe such an error, so VM.Execute checks for it once
+// athcon_execute returns and reports it as an ATHCON_INTERNAL_ERROR, with Err
+// set to the original error for callers that want the underlying cause.
+type HostError struct {
+	// athcon-compatible error code; always ATHCON_INTERNAL_ERROR, kept here
+	// so callers can switch on Code the same way they do for Error.
+	Code int32
+	Err  error
+}
+
+func (err HostError) Error() string {
+	return fmt.Sprintf("host context error: %v", err.Err)
+}
+
+func (err HostError) Unwrap() error {
+	return err.Err
+}
+
 var (
 	Failure                   = Error{Code: C.ATHCON_FAILURE}
 	Revert                    = Error{Code: C.ATHCON_REVERT}
@@ -84,9 +105,18 @@ const (
 	LatestStableRevision Revision = C.ATHCON_LATEST_STABLE_REVISION
 )
 
+// Library is a loaded athcon shared library. Library values returned by
+// LoadLibrary/LoadLibraryWithOptions for the same path share one underlying
+// dynamic-library handle (see sharedLibrary in loader.go); each Close
+// only releases that handle once every Library sharing it has closed.
 type Library struct {
-	// handle to the opened shared library. Must be closed with Dlclose.
-	libHandle uintptr
+	// key identifies the sharedLibrary backing this Library, so Close
+	// knows which one to release.
+	key    string
+	shared *sharedLibrary
+	vmName string
+
+	resolveOnce sync.Once
 
 	create func() *C.struct_athcon_vm
 
@@ -96,29 +126,48 @@ type Library struct {
 	freeBytes func(*C.athcon_bytes)
 }
 
+// LoadLibrary opens the shared library at path, the same as
+// LoadLibraryWithOptions(path, LoaderOptions{}).
 func LoadLibrary(path string) (*Library, error) {
-	libHandle, err := purego.Dlopen(path, purego.RTLD_NOW|purego.RTLD_GLOBAL)
-	if err != nil {
-		return nil, fmt.Errorf("loading library: %v", err)
-	}
+	return LoadLibraryWithOptions(path, LoaderOptions{})
+}
 
+// LoadLibraryWithOptions opens the shared library at path, or returns a
+// Library sharing the handle an earlier call already opened for the same
+// path. See LoaderOptions for what can be customized.
+func LoadLibraryWithOptions(path string, opts LoaderOptions) (*Library, error) {
 	filename := filepath.Base(path)
 	filename = strings.TrimSuffix(filename, filepath.Ext(filename))
 	vmName := strings.TrimPrefix(filename, "lib")
 
-	lib := &Library{
-		libHandle: libHandle,
+	shared, err := openShared(path, opts)
+	if err != nil {
+		return nil, err
 	}
-	purego.RegisterLibFunc(&lib.create, libHandle, "athcon_create_"+vmName)
-	purego.RegisterLibFunc(&lib.encodeTxSpawn, libHandle, "athcon_encode_tx_spawn")
-	purego.RegisterLibFunc(&lib.encodeTxSpend, libHandle, "athcon_encode_tx_spend")
 
-	purego.RegisterLibFunc(&lib.freeBytes, libHandle, "athcon_free_bytes")
+	lib := &Library{key: path, shared: shared, vmName: vmName}
+	if !opts.LazySymbols {
+		lib.resolveSymbols()
+	}
 	return lib, nil
 }
 
+// resolveSymbols registers the library's exported functions against
+// lib.shared.handle. It is safe to call repeatedly: with LazySymbols it
+// runs on first use of any of them, otherwise it has already run once in
+// LoadLibraryWithOptions.
+func (l *Library) resolveSymbols() {
+	l.resolveOnce.Do(func() {
+		handle := l.shared.handle
+		purego.RegisterLibFunc(&l.create, handle, "athcon_create_"+l.vmName)
+		purego.RegisterLibFunc(&l.encodeTxSpawn, handle, "athcon_encode_tx_spawn")
+		purego.RegisterLibFunc(&l.encodeTxSpend, handle, "athcon_encode_tx_spend")
+		purego.RegisterLibFunc(&l.freeBytes, handle, "athcon_free_bytes")
+	})
+}
+
 func (l *Library) Close() {
-	purego.Dlclose(l.libHandle)
+	closeShared(l.key)
 }
 
 type VM struct {
@@ -127,19 +176,36 @@ type VM struct {
 	handle *C.struct_athcon_vm
 }
 
-// Load loads the VM from the shared library and returns an instance of VM.
+// Load loads the VM from the shared library and returns an instance of VM,
+// the same as LoadWithOptions(path, LoaderOptions{}).
 //
 // It is the caller's responsibility to call Destroy on the VM instance when it
 // is no longer needed.
 func Load(path string) (*VM, error) {
-	lib, err := LoadLibrary(path)
+	return LoadWithOptions(path, LoaderOptions{})
+}
+
+// LoadWithOptions loads the VM from the shared library at path, applying
+// opts. See LoaderOptions for what can be customized.
+//
+// It is the caller's responsibility to call Destroy on the VM instance when
+// it is no longer needed.
+func LoadWithOptions(path string, opts LoaderOptions) (*VM, error) {
+	lib, err := LoadLibraryWithOptions(path, opts)
 	if err != nil {
 		return nil, err
 	}
+	lib.resolveSymbols()
 	vmHandle := lib.create()
 	if vmHandle == nil {
+		lib.Close()
 		return nil, fmt.Errorf("failed to create VM")
 	}
+	if opts.ABIVersion != 0 && int(vmHandle.abi_version) != opts.ABIVersion {
+		C.athcon_destroy(vmHandle)
+		lib.Close()
+		return nil, fmt.Errorf("athcon: %s reports ABI version %d, want %d", path, vmHandle.abi_version, opts.ABIVersion)
+	}
 	return &VM{Lib: lib, handle: vmHandle}, nil
 }
 
@@ -208,6 +274,7 @@ type Result struct {
 	Output    []byte
 	GasLeft   int64
 	GasRefund int64
+	Logs      []Log
 }
 
 func (vm *VM) Execute(
@@ -244,7 +311,8 @@ func (vm *VM) Execute(
 		msg.input_size = C.size_t(len(input))
 	}
 
-	ctxHandle := cgo.NewHandle(ctx)
+	state := &executionState{ctx: ctx, static: kind == StaticCall}
+	ctxHandle := cgo.NewHandle(state)
 
 	hostInterface := newHostInterface()
 	result := C.athcon_execute(
@@ -260,9 +328,20 @@ func (vm *VM) Execute(
 
 	res.Output = C.GoBytes(unsafe.Pointer(result.output_data), C.int(result.output_size))
 	res.GasLeft = int64(result.gas_left)
+	res.Logs = goLogs(result.logs, result.logs_size)
 	if result.status_code != C.ATHCON_SUCCESS {
 		err = Error{Code: result.status_code}
 	}
+	if state.err != nil {
+		// A host-side athcon Error (e.g. StaticModeViolation) already carries
+		// its own status code; anything else is an unexpected backend
+		// failure reported as an internal error.
+		if e, ok := state.err.(Error); ok {
+			err = e
+		} else {
+			err = HostError{Code: C.ATHCON_INTERNAL_ERROR, Err: state.err}
+		}
+	}
 
 	if result.release != nil {
 		C.athcon_release_result(&result)
@@ -288,6 +367,7 @@ func athconAddress(address Address) *C.athcon_address {
 }
 
 func (l *Library) EncodeTxSpawn(pubkey Bytes32) []byte {
+	l.resolveSymbols()
 	encoded := l.encodeTxSpawn(athconBytes32(pubkey))
 	defer l.freeBytes(encoded)
 	tx := C.GoBytes(unsafe.Pointer(encoded.ptr), C.int(encoded.size))
@@ -295,6 +375,7 @@ func (l *Library) EncodeTxSpawn(pubkey Bytes32) []byte {
 }
 
 func (l *Library) EncodeTxSpend(recipient Address, nonce uint64) []byte {
+	l.resolveSymbols()
 	encoded := l.encodeTxSpend(
 		athconAddress(recipient),
 		C.uint64_t(nonce),
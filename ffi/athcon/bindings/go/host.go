@@ -16,6 +16,7 @@ struct athcon_tx_context getTxContext(void *ctx);
 athcon_bytes32 getBlockHash(void *ctx, long long int number);
 struct athcon_result call(void *ctx, struct athcon_message *msg);
 athcon_address spawn(void *ctx, uint8_t *blob, size_t len);
+void emitLog(void *ctx, athcon_address *addr, uint8_t *data, size_t data_size, athcon_bytes32 *topics, size_t topics_count);
 */
 import "C"
 import (
@@ -28,7 +29,9 @@ import (
 type CallKind int
 
 const (
-	Call CallKind = C.ATHCON_CALL
+	Call         CallKind = C.ATHCON_CALL
+	StaticCall   CallKind = C.ATHCON_STATICCALL
+	DelegateCall CallKind = C.ATHCON_DELEGATECALL
 )
 
 type StorageStatus int
@@ -68,6 +71,18 @@ func goByteSlice(data *C.uint8_t, size C.size_t) []byte {
 	return (*[1 << 30]byte)(unsafe.Pointer(data))[:size:size]
 }
 
+func goTopics(topics *C.athcon_bytes32, count C.size_t) []Bytes32 {
+	if count == 0 {
+		return nil
+	}
+	cTopics := (*[1 << 30]C.athcon_bytes32)(unsafe.Pointer(topics))[:count:count]
+	out := make([]Bytes32, count)
+	for i, topic := range cTopics {
+		out[i] = goHash(topic)
+	}
+	return out
+}
+
 // TxContext contains information about current transaction and block.
 type TxContext struct {
 	GasPrice    uint64
@@ -81,45 +96,93 @@ type TxContext struct {
 
 type HostContext interface {
 	AccountExists(addr Address) bool
-	GetStorage(addr Address, key Bytes32) Bytes32
-	SetStorage(addr Address, key Bytes32, value Bytes32) StorageStatus
-	GetBalance(addr Address) uint64
+	GetStorage(addr Address, key Bytes32) (Bytes32, error)
+	SetStorage(addr Address, key Bytes32, value Bytes32) (StorageStatus, error)
+	GetBalance(addr Address) (uint64, error)
 	GetTxContext() TxContext
-	GetBlockHash(number int64) Bytes32
+	GetBlockHash(number int64) (Bytes32, error)
 	Call(kind CallKind, recipient Address, sender Address, value uint64, input []byte, gas int64, depth int) (
 		output []byte, gasLeft int64, err error)
 	Spawn(blob []byte) Address
 	Deploy(code []byte) Address
+	EmitLog(addr Address, data []byte, topics []Bytes32)
+}
+
+// Snapshotter is an optional capability for a HostContext: one that can
+// roll back everything it did since a point in time. The call bridge below
+// type-asserts for it and, when present, snapshots before dispatching to
+// Call and reverts if the nested execution does not succeed, making
+// nested-call atomicity a VM-level guarantee rather than something every
+// HostContext has to remember to implement itself.
+type Snapshotter interface {
+	Snapshot() int
+	RevertToSnapshot(id int)
+}
+
+// executionState carries the user's HostContext across the cgo boundary
+// along with the last error (if any) one of its methods returned. athcon's C
+// host interface has no room for a Go error in its return types, so
+// VM.Execute records it here and checks it once athcon_execute returns.
+//
+// static records whether this execution is a StaticCall. An ancestor call
+// being static must also block state-mutating operations in its
+// descendants; that propagates for free because a descendant only becomes
+// static by the `call` bridge below forcing its kind to StaticCall before
+// invoking the user HostContext.
+type executionState struct {
+	ctx    HostContext
+	err    error
+	static bool
+}
+
+func contextState(pCtx unsafe.Pointer) *executionState {
+	return (*cgo.Handle)(pCtx).Value().(*executionState)
 }
 
 //export accountExists
 func accountExists(pCtx unsafe.Pointer, pAddr *C.athcon_address) C.bool {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
-	return C.bool(ctx.AccountExists(goAddress(*pAddr)))
+	state := contextState(pCtx)
+	return C.bool(state.ctx.AccountExists(goAddress(*pAddr)))
 }
 
 //export getStorage
 func getStorage(pCtx unsafe.Pointer, pAddr *C.athcon_address, pKey *C.athcon_bytes32) C.athcon_bytes32 {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
-	return *athconBytes32(ctx.GetStorage(goAddress(*pAddr), goHash(*pKey)))
+	state := contextState(pCtx)
+	value, err := state.ctx.GetStorage(goAddress(*pAddr), goHash(*pKey))
+	if err != nil {
+		state.err = err
+	}
+	return *athconBytes32(value)
 }
 
 //export setStorage
 func setStorage(pCtx unsafe.Pointer, pAddr *C.athcon_address, pKey *C.athcon_bytes32, pVal *C.athcon_bytes32) C.enum_athcon_storage_status {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
-	return C.enum_athcon_storage_status(ctx.SetStorage(goAddress(*pAddr), goHash(*pKey), goHash(*pVal)))
+	state := contextState(pCtx)
+	if state.static {
+		state.err = StaticModeViolation
+		return C.enum_athcon_storage_status(0)
+	}
+	status, err := state.ctx.SetStorage(goAddress(*pAddr), goHash(*pKey), goHash(*pVal))
+	if err != nil {
+		state.err = err
+	}
+	return C.enum_athcon_storage_status(status)
 }
 
 //export getBalance
 func getBalance(pCtx unsafe.Pointer, pAddr *C.athcon_address) C.uint64_t {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
-	return C.uint64_t(ctx.GetBalance(goAddress(*pAddr)))
+	state := contextState(pCtx)
+	balance, err := state.ctx.GetBalance(goAddress(*pAddr))
+	if err != nil {
+		state.err = err
+	}
+	return C.uint64_t(balance)
 }
 
 //export getTxContext
 func getTxContext(pCtx unsafe.Pointer) C.struct_athcon_tx_context {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
-	txContext := ctx.GetTxContext()
+	state := contextState(pCtx)
+	txContext := state.ctx.GetTxContext()
 
 	return C.struct_athcon_tx_context{
 		C.uint64_t(txContext.GasPrice),
@@ -133,18 +196,42 @@ func getTxContext(pCtx unsafe.Pointer) C.struct_athcon_tx_context {
 
 //export getBlockHash
 func getBlockHash(pCtx unsafe.Pointer, number int64) C.athcon_bytes32 {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
-	return *athconBytes32(ctx.GetBlockHash(number))
+	state := contextState(pCtx)
+	hash, err := state.ctx.GetBlockHash(number)
+	if err != nil {
+		state.err = err
+	}
+	return *athconBytes32(hash)
 }
 
 //export call
 func call(pCtx unsafe.Pointer, msg *C.struct_athcon_message) C.struct_athcon_result {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
+	state := contextState(pCtx)
+
+	if state.static && msg.value != 0 {
+		return C.athcon_make_result(C.ATHCON_STATIC_MODE_VIOLATION, 0, nil, 0)
+	}
 
 	kind := CallKind(msg.kind)
-	output, gasLeft, err := ctx.Call(kind, goAddress(msg.recipient), goAddress(msg.sender), uint64(msg.value),
+	if state.static {
+		// A static ancestor forces every descendant call to be static too,
+		// regardless of what kind the guest itself requested.
+		kind = StaticCall
+	}
+
+	snapshotter, canRevert := state.ctx.(Snapshotter)
+	var snapshot int
+	if canRevert {
+		snapshot = snapshotter.Snapshot()
+	}
+
+	output, gasLeft, err := state.ctx.Call(kind, goAddress(msg.recipient), goAddress(msg.sender), uint64(msg.value),
 		goByteSlice(msg.input_data, msg.input_size), int64(msg.gas), int(msg.depth))
 
+	if err != nil && canRevert {
+		snapshotter.RevertToSnapshot(snapshot)
+	}
+
 	statusCode := C.enum_athcon_status_code(0)
 	if err != nil {
 		// Wrap unknown error types with a catch-all type
@@ -167,16 +254,34 @@ func call(pCtx unsafe.Pointer, msg *C.struct_athcon_message) C.struct_athcon_res
 
 //export spawn
 func spawn(pCtx unsafe.Pointer, pBlob *C.uint8_t, blobSize C.size_t) C.athcon_address {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
+	state := contextState(pCtx)
+	if state.static {
+		state.err = StaticModeViolation
+		return C.athcon_address{}
+	}
 	blob := C.GoBytes(unsafe.Pointer(pBlob), C.int(blobSize))
-	return *athconAddress(ctx.Spawn(blob))
+	return *athconAddress(state.ctx.Spawn(blob))
 }
 
 //export deploy
 func deploy(pCtx unsafe.Pointer, pCode *C.uint8_t, codeSize C.size_t) C.athcon_address {
-	ctx := (*cgo.Handle)(pCtx).Value().(HostContext)
+	state := contextState(pCtx)
+	if state.static {
+		state.err = StaticModeViolation
+		return C.athcon_address{}
+	}
 	code := C.GoBytes(unsafe.Pointer(pCode), C.int(codeSize))
-	return *athconAddress(ctx.Deploy(code))
+	return *athconAddress(state.ctx.Deploy(code))
+}
+
+//export emitLog
+func emitLog(pCtx unsafe.Pointer, pAddr *C.athcon_address, pData *C.uint8_t, dataSize C.size_t, pTopics *C.athcon_bytes32, topicsCount C.size_t) {
+	state := contextState(pCtx)
+	if state.static {
+		state.err = StaticModeViolation
+		return
+	}
+	state.ctx.EmitLog(goAddress(*pAddr), goByteSlice(pData, dataSize), goTopics(pTopics, topicsCount))
 }
 
 func newHostInterface() *C.struct_athcon_host_interface {
@@ -189,5 +294,6 @@ func newHostInterface() *C.struct_athcon_host_interface {
 		get_block_hash: (C.athcon_get_block_hash_fn)(C.getBlockHash),
 		call:           (C.athcon_call_fn)(C.call),
 		spawn:          (C.athcon_spawn_fn)(C.spawn),
+		emit_log:       (C.athcon_emit_log_fn)(C.emitLog),
 	}
 }
@@ -7,7 +7,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"runtime/cgo"
 	"testing"
+	"unsafe"
 
 	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/stretchr/testify/require"
@@ -29,6 +31,10 @@ type testHostContext struct {
 	vm       *VM
 	balances map[Address]uint64
 	programs map[Address][]byte
+	storage  map[Address]map[Bytes32]Bytes32
+	logs     []Log
+
+	journal []func()
 }
 
 func newHost(vm *VM) *testHostContext {
@@ -36,24 +42,49 @@ func newHost(vm *VM) *testHostContext {
 		vm:       vm,
 		balances: make(map[Address]uint64),
 		programs: make(map[Address][]byte),
+		storage:  make(map[Address]map[Bytes32]Bytes32),
 	}
 
 }
 
+// Snapshot and RevertToSnapshot make testHostContext a Snapshotter, so the
+// cgo call bridge snapshots and reverts it around nested calls; Call below
+// also uses them directly to undo its own balance transfer if the nested
+// execution it dispatches to fails. Only those balance transfers are
+// journaled: storage writes, spawned programs and logs are not undone.
+func (host *testHostContext) Snapshot() int {
+	return len(host.journal)
+}
+
+func (host *testHostContext) RevertToSnapshot(id int) {
+	for i := len(host.journal) - 1; i >= id; i-- {
+		host.journal[i]()
+	}
+	host.journal = host.journal[:id]
+}
+
 func (host *testHostContext) AccountExists(addr Address) bool {
 	return false
 }
 
-func (host *testHostContext) GetStorage(addr Address, key Bytes32) Bytes32 {
-	return Bytes32{}
+func (host *testHostContext) GetStorage(addr Address, key Bytes32) (Bytes32, error) {
+	return host.storage[addr][key], nil
 }
 
-func (host *testHostContext) SetStorage(addr Address, key Bytes32, value Bytes32) (status StorageStatus) {
-	return StorageAdded
+func (host *testHostContext) SetStorage(addr Address, key Bytes32, value Bytes32) (status StorageStatus, err error) {
+	prev := host.storage[addr][key]
+	if host.storage[addr] == nil {
+		host.storage[addr] = make(map[Bytes32]Bytes32)
+	}
+	host.storage[addr][key] = value
+	if prev == value {
+		return StorageAssigned, nil
+	}
+	return StorageAdded, nil
 }
 
-func (host *testHostContext) GetBalance(addr Address) uint64 {
-	return host.balances[addr]
+func (host *testHostContext) GetBalance(addr Address) (uint64, error) {
+	return host.balances[addr], nil
 }
 
 func (host *testHostContext) GetTxContext() TxContext {
@@ -62,8 +93,8 @@ func (host *testHostContext) GetTxContext() TxContext {
 	return txContext
 }
 
-func (host *testHostContext) GetBlockHash(number int64) Bytes32 {
-	return Bytes32{}
+func (host *testHostContext) GetBlockHash(number int64) (Bytes32, error) {
+	return Bytes32{}, nil
 }
 
 func (host *testHostContext) Call(
@@ -75,10 +106,32 @@ func (host *testHostContext) Call(
 	gas int64,
 	depth int,
 ) (output []byte, gasLeft int64, err error) {
+	if kind == DelegateCall {
+		// DelegateCall runs recipient's code in the caller's own
+		// address/storage/balance context, so there is no value transfer
+		// and the nested execution acts as sender, not recipient.
+		p, ok := host.programs[recipient]
+		if !ok {
+			return nil, gas, nil
+		}
+		encoded := EncodedExecutionPayload(nil, input)
+		result, err := host.vm.Execute(host, Frontier, kind, depth+1, gas, sender, sender, encoded, 0, p)
+		if err != nil {
+			return nil, gas, fmt.Errorf("executing call: %w", err)
+		}
+		return result.Output, result.GasLeft, nil
+	}
+
 	if host.balances[sender] < value {
 		return nil, 0, errors.New("insufficient balance")
 	}
 
+	snapshot := host.Snapshot()
+	prevSender, prevRecipient := host.balances[sender], host.balances[recipient]
+	host.journal = append(host.journal, func() {
+		host.balances[sender] = prevSender
+		host.balances[recipient] = prevRecipient
+	})
 	host.balances[sender] -= value
 	host.balances[recipient] += value
 
@@ -89,8 +142,11 @@ func (host *testHostContext) Call(
 	}
 
 	encoded := EncodedExecutionPayload(nil, input)
-	result, err := host.vm.Execute(host, Frontier, Call, depth+1, gas, recipient, sender, encoded, 0, p)
+	result, err := host.vm.Execute(host, Frontier, kind, depth+1, gas, recipient, sender, encoded, 0, p)
 	if err != nil {
+		// Nested-call atomicity is a VM-level guarantee: a failed nested
+		// execution must not leave the attempted transfer applied.
+		host.RevertToSnapshot(snapshot)
 		return nil, gas, fmt.Errorf("executing call: %w", err)
 	}
 
@@ -107,6 +163,10 @@ func (host *testHostContext) Deploy(code []byte) Address {
 	return Address{}
 }
 
+func (host *testHostContext) EmitLog(addr Address, data []byte, topics []Bytes32) {
+	host.logs = append(host.logs, Log{Address: addr, Data: data, Topics: topics})
+}
+
 func randomAddress() Address {
 	var a Address
 	_, err := rand.Read(a[:])
@@ -177,6 +237,72 @@ func TestSpawn(t *testing.T) {
 	require.Contains(t, host.programs, Address(result.Output))
 }
 
+// TestStaticCallBlocksSetStorage exercises the host bridge directly: a guest
+// syscall made from a static execution must be rejected with
+// StaticModeViolation before it ever reaches the user HostContext.
+func TestStaticCallBlocksSetStorage(t *testing.T) {
+	host := newHost(nil)
+	state := &executionState{ctx: host, static: true}
+	handle := cgo.NewHandle(state)
+	defer handle.Delete()
+
+	addr := randomAddress()
+	key := Bytes32{1}
+	val := Bytes32{2}
+	setStorage(unsafe.Pointer(&handle), athconAddress(addr), athconBytes32(key), athconBytes32(val))
+
+	require.ErrorIs(t, state.err, StaticModeViolation)
+	require.Empty(t, host.storage[addr])
+}
+
+// TestDelegateCallPreservesCallerContext checks that testHostContext.Call
+// honors a DelegateCall's kind by running the callee's code as the caller,
+// rather than as the callee: the nested execution sees the caller's own
+// balance, not the callee's.
+func TestDelegateCallPreservesCallerContext(t *testing.T) {
+	vm, _ := Load(libPath(t))
+	defer vm.Destroy()
+
+	host := newHost(vm)
+	caller := randomAddress()
+	callee := randomAddress()
+	host.balances[caller] = 1000
+	host.programs[callee] = MINIMAL_TEST_CODE
+
+	output, _, err := host.Call(DelegateCall, callee, caller, 0, nil, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, output, 32)
+
+	balance := binary.LittleEndian.Uint64(output)
+	require.Equal(t, host.balances[caller], balance)
+	require.Zero(t, host.balances[callee])
+}
+
+// TestCallRevertsBalanceOnNestedFailure spawns a wallet, then calls it with
+// a value transfer but far too little gas for it to run at all. The nested
+// execution fails with OutOfGas, and the transfer Call applied before
+// dispatching to it must not survive that failure.
+func TestCallRevertsBalanceOnNestedFailure(t *testing.T) {
+	vm, _ := Load(libPath(t))
+	defer vm.Destroy()
+
+	host := newHost(vm)
+	principal := randomAddress()
+	host.balances[principal] = 1000
+
+	pubkey := Bytes32([32]byte{1, 1, 2, 2, 3, 3, 4, 4})
+	executionPayload := EncodedExecutionPayload(nil, vm.Lib.EncodeTxSpawn(pubkey))
+	result, err := vm.Execute(host, Frontier, Call, 1, 1000000, principal, principal, executionPayload, 0, WALLET_TEST)
+	require.NoError(t, err)
+	walletAddress := Address(result.Output)
+
+	_, _, err = host.Call(Call, walletAddress, principal, 100, nil, 1, 0)
+	require.Error(t, err)
+
+	require.Equal(t, uint64(1000), host.balances[principal])
+	require.Zero(t, host.balances[walletAddress])
+}
+
 func TestSpend(t *testing.T) {
 	vm, _ := Load(libPath(t))
 	defer vm.Destroy()
@@ -212,6 +338,51 @@ func TestSpend(t *testing.T) {
 	require.Equal(t, host.balances[principal], uint64(900))
 }
 
+func TestEmitAndDecodeLog(t *testing.T) {
+	vm, _ := Load(libPath(t))
+	defer vm.Destroy()
+
+	host := newHost(vm)
+	// Step 1: Spawn wallet
+	principal := Address{1, 2, 3, 4}
+	var walletAddress Address
+	{
+		pubkey := Bytes32([32]byte{1, 1, 2, 2, 3, 3, 4, 4})
+		executionPayload := EncodedExecutionPayload(nil, vm.Lib.EncodeTxSpawn(pubkey))
+
+		result, err := vm.Execute(host, Frontier, Call, 1, 10000, principal, principal, executionPayload, 0, WALLET_TEST)
+		require.NoError(t, err)
+		require.Len(t, result.Output, 24)
+
+		walletAddress = Address(result.Output)
+	}
+	// Step 2: Send coins, which the wallet template emits a "Spend" event for.
+	host.balances[principal] = 1000
+	recipient := randomAddress()
+
+	executionPayload := EncodedExecutionPayload(
+		host.programs[walletAddress],
+		vm.Lib.EncodeTxSpend(recipient, 100),
+	)
+	result, err := vm.Execute(host, Frontier, Call, 1, 10000, principal, principal, executionPayload, 0, WALLET_TEST)
+	require.NoError(t, err)
+	require.Len(t, result.Logs, 1)
+
+	// Step 3: Decode the emitted log.
+	decoder := vm.Lib.NewLogDecoder("Spend(address,uint64)", false)
+	var event struct {
+		Recipient Address
+		Amount    uint64
+	}
+	require.NoError(t, decoder.UnpackLog(&event, result.Logs[0]))
+	require.Equal(t, recipient, event.Recipient)
+	require.Equal(t, uint64(100), event.Amount)
+
+	// An event signature mismatch is reported as a distinct sentinel error.
+	wrongDecoder := vm.Lib.NewLogDecoder("Transfer(address,uint64)", false)
+	require.ErrorIs(t, wrongDecoder.UnpackLog(&event, result.Logs[0]), ErrEventSignatureMismatch)
+}
+
 func TestVerify(t *testing.T) {
 	vm, _ := Load(libPath(t))
 	defer vm.Destroy()
@@ -0,0 +1,37 @@
+//go:build android
+
+package load
+
+/*
+#cgo LDFLAGS: -ldl
+#include <dlfcn.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// LoadLibrary opens path via dlopen. purego has no android support (bionic
+// exposes dlopen only through cgo, which Android requires anyway), so this
+// variant calls libdl directly instead of going through the purego
+// trampoline the other unix targets use.
+func LoadLibrary(path string) (uintptr, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	handle := C.dlopen(cPath, C.RTLD_NOW|C.RTLD_GLOBAL)
+	if handle == nil {
+		return 0, fmt.Errorf("dlopen %s: %s", path, C.GoString(C.dlerror()))
+	}
+	return uintptr(handle), nil
+}
+
+func CloseLibrary(handle uintptr) error {
+	if C.dlclose(unsafe.Pointer(handle)) != 0 {
+		return fmt.Errorf("dlclose: %s", C.GoString(C.dlerror()))
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build faketime
+
+package load
+
+import "errors"
+
+// LoadLibrary and CloseLibrary are stubbed out for faketime test builds,
+// which run in a hermetic environment where dlopen-ing a real shared
+// library isn't available. Callers that need athcon under faketime must
+// supply a fake VM instead of loading the compiled shared library.
+func LoadLibrary(path string) (uintptr, error) {
+	return 0, errors.New("load: dlopen unavailable in faketime builds")
+}
+
+func CloseLibrary(handle uintptr) error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package athcon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/athenavm/athena/ffi/athcon/bindings/go/internal/load"
+)
+
+// LoaderOptions customizes how LoadLibraryWithOptions/LoadWithOptions locate
+// and open a shared library, beyond what LoadLibrary and Load do by default.
+type LoaderOptions struct {
+	// LibraryBytes, if set, is written to a temp file and that is opened
+	// instead of the path argument itself -- for callers embedding a
+	// shared library (e.g. via go:embed) rather than shipping it as a
+	// file on disk. The path argument is still used as the dedup key for
+	// the shared handle and to derive the athcon_create_<name> symbol.
+	LibraryBytes []byte
+
+	// ABIVersion, if non-zero, is checked against the created VM's
+	// reported ABI version; a mismatch fails Load immediately instead of
+	// surfacing as a harder-to-diagnose failure once the VM runs.
+	ABIVersion int
+
+	// LazySymbols defers resolving a Library's exported functions until
+	// they are first used, instead of eagerly when it is loaded.
+	LazySymbols bool
+}
+
+// sharedLibrary is the open dynamic-library handle behind every Library
+// opened for the same path: LoadLibrary(path) called twice returns two
+// Library values wrapping one sharedLibrary, and the underlying handle is
+// only Dlclose-d once the last of them calls Close.
+type sharedLibrary struct {
+	handle   uintptr
+	refCount int
+}
+
+var (
+	sharedLibrariesMu sync.Mutex
+	sharedLibraries   = make(map[string]*sharedLibrary)
+)
+
+// openShared returns the sharedLibrary for key (path), opening it if this
+// is the first reference and incrementing refCount otherwise.
+func openShared(key string, opts LoaderOptions) (*sharedLibrary, error) {
+	sharedLibrariesMu.Lock()
+	defer sharedLibrariesMu.Unlock()
+
+	if shared, ok := sharedLibraries[key]; ok {
+		shared.refCount++
+		return shared, nil
+	}
+
+	path := key
+	if len(opts.LibraryBytes) > 0 {
+		tmp, err := os.CreateTemp("", filepath.Base(key))
+		if err != nil {
+			return nil, fmt.Errorf("writing embedded library to temp file: %w", err)
+		}
+		defer tmp.Close()
+		if _, err := tmp.Write(opts.LibraryBytes); err != nil {
+			return nil, fmt.Errorf("writing embedded library to temp file: %w", err)
+		}
+		path = tmp.Name()
+	}
+
+	handle, err := load.LoadLibrary(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading library: %v", err)
+	}
+
+	shared := &sharedLibrary{handle: handle, refCount: 1}
+	sharedLibraries[key] = shared
+	return shared, nil
+}
+
+// closeShared decrements key's refcount and Dlclose-s its handle once the
+// count reaches zero.
+func closeShared(key string) {
+	sharedLibrariesMu.Lock()
+	defer sharedLibrariesMu.Unlock()
+
+	shared, ok := sharedLibraries[key]
+	if !ok {
+		return
+	}
+	shared.refCount--
+	if shared.refCount > 0 {
+		return
+	}
+	load.CloseLibrary(shared.handle)
+	delete(sharedLibraries, key)
+}
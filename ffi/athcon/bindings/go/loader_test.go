@@ -0,0 +1,60 @@
+package athcon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadLibrarySharesHandle checks that two LoadLibrary calls for the
+// same path share one sharedLibrary (refCount 2), and that the underlying
+// handle is only released once both Library values have Close-d.
+func TestLoadLibrarySharesHandle(t *testing.T) {
+	path := libPath(t)
+
+	first, err := LoadLibrary(path)
+	require.NoError(t, err)
+	defer first.Close()
+	baseline := first.shared.refCount
+
+	second, err := LoadLibrary(path)
+	require.NoError(t, err)
+
+	require.Same(t, first.shared, second.shared)
+	require.Equal(t, baseline+1, first.shared.refCount)
+
+	second.Close()
+	require.Equal(t, baseline, first.shared.refCount)
+	_, stillOpen := sharedLibraries[path]
+	require.True(t, stillOpen)
+}
+
+// TestLoadLibraryWithOptionsLazySymbols checks that symbols are not
+// registered until first use when LazySymbols is set.
+func TestLoadLibraryWithOptionsLazySymbols(t *testing.T) {
+	lib, err := LoadLibraryWithOptions(libPath(t), LoaderOptions{LazySymbols: true})
+	require.NoError(t, err)
+	defer lib.Close()
+
+	require.Nil(t, lib.create)
+	lib.resolveSymbols()
+	require.NotNil(t, lib.create)
+}
+
+// TestLoadLibraryWithOptionsLibraryBytes checks that a library provided as
+// in-memory bytes loads via a temp-file fallback. The path keeps the real
+// library's file name, since that is what athcon_create_<name> is derived
+// from, but lives in another directory so it is not deduplicated against
+// the library already opened from libPath.
+func TestLoadLibraryWithOptionsLibraryBytes(t *testing.T) {
+	bytes, err := os.ReadFile(libPath(t))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), filepath.Base(libPath(t)))
+	lib, err := LoadLibraryWithOptions(path, LoaderOptions{LibraryBytes: bytes})
+	require.NoError(t, err)
+	defer lib.Close()
+	require.NotNil(t, lib.create)
+}
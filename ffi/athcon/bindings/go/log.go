@@ -0,0 +1,90 @@
+package athcon
+
+/*
+#include <athcon/athcon.h>
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/zeebo/blake3"
+)
+
+var (
+	// ErrNoEventSignature is returned by UnpackLog when the log has no topics,
+	// so there is no topic[0] to compare against the registered event signature.
+	ErrNoEventSignature = errors.New("athcon: log has no event signature topic")
+
+	// ErrEventSignatureMismatch is returned by UnpackLog when topic[0] does not
+	// match the event signature the LogDecoder was created for.
+	ErrEventSignatureMismatch = errors.New("athcon: log topic does not match registered event signature")
+)
+
+// Log represents a single event emitted by a program during execution via
+// HostContext.EmitLog.
+type Log struct {
+	Address Address
+	Data    []byte
+	Topics  []Bytes32
+}
+
+func goLogs(logs *C.struct_athcon_log, size C.size_t) []Log {
+	if size == 0 {
+		return nil
+	}
+	cLogs := (*[1 << 30]C.struct_athcon_log)(unsafe.Pointer(logs))[:size:size]
+	out := make([]Log, size)
+	for i, l := range cLogs {
+		out[i] = Log{
+			Address: goAddress(l.address),
+			// Copy, unlike goByteSlice: this backing memory is part of the
+			// athcon_result freed by athcon_release_result once VM.Execute
+			// returns, but Log.Data is returned to and kept by the caller.
+			Data:   C.GoBytes(unsafe.Pointer(l.data), C.int(l.data_size)),
+			Topics: goTopics(l.topics, l.topics_count),
+		}
+	}
+	return out
+}
+
+// LogDecoder unpacks Logs emitted for a single, registered event signature
+// into a caller-supplied struct, mirroring the go-ethereum bound-contract
+// UnpackLog flow.
+type LogDecoder struct {
+	signature Bytes32
+	anonymous bool
+}
+
+// NewLogDecoder hashes an event signature (e.g. "Transfer(address,uint64)")
+// with blake3 and returns a LogDecoder that can unpack Logs whose topic[0]
+// is that hash. Nothing is recorded on the Library itself.
+//
+// If anonymous is true, the decoder does not require (or check) a topic[0]
+// event signature match, matching Solidity's "anonymous event" opt-in.
+func (l *Library) NewLogDecoder(eventSignature string, anonymous bool) *LogDecoder {
+	hasher := blake3.New()
+	hasher.Write([]byte(eventSignature))
+	var sig Bytes32
+	hasher.Digest().Read(sig[:])
+
+	return &LogDecoder{signature: sig, anonymous: anonymous}
+}
+
+// UnpackLog decodes log.Data into out (a pointer to a caller-supplied struct)
+// using scale encoding, after verifying that the log's topic[0] matches the
+// event signature this decoder was registered for (unless the decoder is
+// anonymous).
+func (d *LogDecoder) UnpackLog(out any, log Log) error {
+	if !d.anonymous {
+		if len(log.Topics) == 0 {
+			return ErrNoEventSignature
+		}
+		if log.Topics[0] != d.signature {
+			return ErrEventSignatureMismatch
+		}
+	}
+
+	return scale.Unmarshal(log.Data, out)
+}
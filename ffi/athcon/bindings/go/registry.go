@@ -0,0 +1,130 @@
+package athcon
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// SelectorRegistry maps human-readable method names to their
+// blake3-derived MethodSelector and back, optionally with the Go types of
+// each method's scale-encoded arguments, so a selector can be resolved back
+// to a name (and its Payload decoded) for debugging traces, RPC gateways,
+// and tx builders without every caller reimplementing the lookup table.
+//
+// A SelectorRegistry is safe for concurrent use.
+type SelectorRegistry struct {
+	mu       sync.RWMutex
+	names    map[MethodSelector]string
+	argTypes map[MethodSelector][]reflect.Type
+}
+
+// NewSelectorRegistry returns an empty SelectorRegistry.
+func NewSelectorRegistry() *SelectorRegistry {
+	return &SelectorRegistry{
+		names:    make(map[MethodSelector]string),
+		argTypes: make(map[MethodSelector][]reflect.Type),
+	}
+}
+
+// Register derives name's MethodSelector with FromString and records it,
+// along with the Go types of its scale-encoded arguments for DecodePayload
+// to use. Pass a zero value of each argument type, in declaration order;
+// a method that takes no decodable arguments can omit argTypes entirely.
+func (r *SelectorRegistry) Register(name string, argTypes ...any) (MethodSelector, error) {
+	selector, err := FromString(name)
+	if err != nil {
+		return MethodSelector{}, err
+	}
+
+	types := make([]reflect.Type, len(argTypes))
+	for i, v := range argTypes {
+		types[i] = reflect.TypeOf(v)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names[selector] = name
+	r.argTypes[selector] = types
+	return selector, nil
+}
+
+// MustSelector registers name, with no argument types, and returns its
+// MethodSelector. It exists for callers building selectors as package-level
+// vars, where a returned error has nowhere useful to go.
+func (r *SelectorRegistry) MustSelector(name string) MethodSelector {
+	selector, err := r.Register(name)
+	if err != nil {
+		panic(fmt.Sprintf("athcon: registering selector %q: %v", name, err))
+	}
+	return selector
+}
+
+// Lookup returns the name and argument types selector was Register-ed with,
+// or ok == false if selector is not registered.
+func (r *SelectorRegistry) Lookup(selector MethodSelector) (name string, argTypes []reflect.Type, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok = r.names[selector]
+	return name, r.argTypes[selector], ok
+}
+
+// DecodePayload looks up p.Selector and, if it was registered with argument
+// types, scale-decodes p.Input into one concrete Go value per type, in the
+// order they were registered. If p.Selector is nil, or is unregistered, or
+// was registered with no argument types, DecodePayload returns ok == false
+// rather than an error, since not every Payload is expected to go through
+// the registry.
+func (r *SelectorRegistry) DecodePayload(p Payload) (name string, args []any, ok bool, err error) {
+	if p.Selector == nil {
+		return "", nil, false, nil
+	}
+
+	name, argTypes, ok := r.Lookup(*p.Selector)
+	if !ok || len(argTypes) == 0 {
+		return name, nil, false, nil
+	}
+
+	decoder := scale.NewDecoder(bytes.NewReader(p.Input))
+	args = make([]any, len(argTypes))
+	for i, t := range argTypes {
+		dst := reflect.New(t)
+		if err := decoder.Decode(dst.Interface()); err != nil {
+			return name, nil, false, fmt.Errorf("decoding argument %d of %q: %w", i, name, err)
+		}
+		args[i] = dst.Elem().Interface()
+	}
+	return name, args, true, nil
+}
+
+// defaultRegistry is the process-wide SelectorRegistry that MethodSelector's
+// String method and the package-level Register/MustSelector/Lookup/
+// DecodePayload functions use.
+var defaultRegistry = NewSelectorRegistry()
+
+// Register derives name's MethodSelector and records it, along with the Go
+// types of its scale-encoded arguments, in the default SelectorRegistry.
+func Register(name string, argTypes ...any) (MethodSelector, error) {
+	return defaultRegistry.Register(name, argTypes...)
+}
+
+// MustSelector registers name, with no argument types, in the default
+// SelectorRegistry and returns its MethodSelector.
+func MustSelector(name string) MethodSelector {
+	return defaultRegistry.MustSelector(name)
+}
+
+// Lookup returns the name and argument types selector was registered with
+// in the default SelectorRegistry, or ok == false if it is not registered.
+func Lookup(selector MethodSelector) (name string, argTypes []reflect.Type, ok bool) {
+	return defaultRegistry.Lookup(selector)
+}
+
+// DecodePayload decodes p using the default SelectorRegistry. See
+// SelectorRegistry.DecodePayload.
+func DecodePayload(p Payload) (name string, args []any, ok bool, err error) {
+	return defaultRegistry.DecodePayload(p)
+}
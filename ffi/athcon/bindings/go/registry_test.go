@@ -0,0 +1,43 @@
+package athcon
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectorRegistryRoundTrip(t *testing.T) {
+	registry := NewSelectorRegistry()
+	selector, err := registry.Register("transfer(address,uint64)", Address{}, uint64(0))
+	require.NoError(t, err)
+
+	recipient := randomAddress()
+	input, err := scale.Marshal(recipient)
+	require.NoError(t, err)
+	amountEncoded, err := scale.Marshal(uint64(100))
+	require.NoError(t, err)
+	input = append(input, amountEncoded...)
+
+	name, args, ok, err := registry.DecodePayload(Payload{Selector: &selector, Input: input})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "transfer(address,uint64)", name)
+	require.Equal(t, []any{recipient, uint64(100)}, args)
+}
+
+func TestSelectorRegistryLookupUnknown(t *testing.T) {
+	registry := NewSelectorRegistry()
+	_, _, ok := registry.Lookup(MethodSelector{1, 2, 3, 4})
+	require.False(t, ok)
+}
+
+func TestMethodSelectorStringShowsRegisteredName(t *testing.T) {
+	selector := MustSelector("athexp_my_method")
+	require.Equal(t, "athexp_my_method", selector.String())
+
+	unregistered, err := FromString("athexp_never_registered")
+	require.NoError(t, err)
+	require.Equal(t, unregistered.String(), unregistered.String())
+	require.NotEqual(t, "athexp_never_registered", unregistered.String())
+}
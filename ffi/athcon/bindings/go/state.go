@@ -0,0 +1,179 @@
+package athcon
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zeebo/blake3"
+)
+
+// StateBackend stores account balances, storage and code, and supports
+// snapshotting so a HostContext can roll back a failed nested call. It is the
+// extension point that lets callers plug in an in-memory map (see the
+// state/memory sub-package) or a persistent, verifiable Merkle trie (see
+// state/trie) without touching the cgo host shim.
+type StateBackend interface {
+	GetBalance(addr Address) (uint64, error)
+	SetBalance(addr Address, balance uint64) error
+	GetStorage(addr Address, key Bytes32) (Bytes32, error)
+	SetStorage(addr Address, key Bytes32, value Bytes32) (StorageStatus, error)
+	GetCode(addr Address) ([]byte, error)
+	SetCode(addr Address, code []byte) error
+	Exists(addr Address) (bool, error)
+	Snapshot() int
+	RevertToSnapshot(id int)
+	Commit() (Bytes32, error)
+}
+
+// StateHostContext is the default HostContext implementation. It delegates
+// all account state to a StateBackend and keeps only the data needed to
+// drive a single execution: the VM used for nested calls and the current
+// TxContext.
+type StateHostContext struct {
+	Backend StateBackend
+	VM      *VM
+	Tx      TxContext
+}
+
+// NewStateHostContext returns a HostContext backed by backend.
+func NewStateHostContext(backend StateBackend, vm *VM, tx TxContext) *StateHostContext {
+	return &StateHostContext{Backend: backend, VM: vm, Tx: tx}
+}
+
+func (h *StateHostContext) AccountExists(addr Address) bool {
+	exists, err := h.Backend.Exists(addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "athcon: state backend error in Exists: %v\n", err)
+		return false
+	}
+	return exists
+}
+
+func (h *StateHostContext) GetStorage(addr Address, key Bytes32) (Bytes32, error) {
+	return h.Backend.GetStorage(addr, key)
+}
+
+func (h *StateHostContext) SetStorage(addr Address, key Bytes32, value Bytes32) (StorageStatus, error) {
+	return h.Backend.SetStorage(addr, key, value)
+}
+
+func (h *StateHostContext) GetBalance(addr Address) (uint64, error) {
+	return h.Backend.GetBalance(addr)
+}
+
+func (h *StateHostContext) GetTxContext() TxContext {
+	return h.Tx
+}
+
+func (h *StateHostContext) GetBlockHash(number int64) (Bytes32, error) {
+	return Bytes32{}, nil
+}
+
+// Snapshot and RevertToSnapshot delegate to the backend, making
+// StateHostContext a Snapshotter: the cgo call bridge uses this to undo a
+// nested call's balance transfer and any state it wrote if the call fails.
+func (h *StateHostContext) Snapshot() int {
+	return h.Backend.Snapshot()
+}
+
+func (h *StateHostContext) RevertToSnapshot(id int) {
+	h.Backend.RevertToSnapshot(id)
+}
+
+// Call applies the value transfer and, if recipient has code, runs it
+// nested. It does not need to roll back that transfer itself on failure:
+// nested-call atomicity is a VM-level guarantee enforced by the cgo call
+// bridge, which snapshots before invoking Call and reverts via
+// RevertToSnapshot above if the nested execution does not succeed.
+func (h *StateHostContext) Call(
+	kind CallKind,
+	recipient Address,
+	sender Address,
+	value uint64,
+	input []byte,
+	gas int64,
+	depth int,
+) (output []byte, gasLeft int64, err error) {
+	if kind == DelegateCall {
+		// DelegateCall runs recipient's code in the caller's own
+		// address/storage/balance context, so there is no value transfer
+		// and the nested execution acts as sender, not recipient.
+		code, err := h.Backend.GetCode(recipient)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(code) == 0 {
+			return nil, gas, nil
+		}
+		encoded := EncodedExecutionPayload(nil, input)
+		result, err := h.VM.Execute(h, Frontier, kind, depth+1, gas, sender, sender, encoded, 0, code)
+		if err != nil {
+			return nil, gas, fmt.Errorf("executing call: %w", err)
+		}
+		return result.Output, result.GasLeft, nil
+	}
+
+	senderBalance, err := h.Backend.GetBalance(sender)
+	if err != nil {
+		return nil, 0, err
+	}
+	if senderBalance < value {
+		return nil, 0, InsufficientBalance
+	}
+	if err := h.Backend.SetBalance(sender, senderBalance-value); err != nil {
+		return nil, 0, err
+	}
+	recipientBalance, err := h.Backend.GetBalance(recipient)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := h.Backend.SetBalance(recipient, recipientBalance+value); err != nil {
+		return nil, 0, err
+	}
+
+	code, err := h.Backend.GetCode(recipient)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(code) == 0 {
+		// No program at recipient; treat this as a plain value transfer.
+		return nil, gas, nil
+	}
+
+	encoded := EncodedExecutionPayload(nil, input)
+	result, err := h.VM.Execute(h, Frontier, kind, depth+1, gas, recipient, sender, encoded, 0, code)
+	if err != nil {
+		return nil, gas, fmt.Errorf("executing call: %w", err)
+	}
+	return result.Output, result.GasLeft, nil
+}
+
+func (h *StateHostContext) Spawn(blob []byte) Address {
+	addr := contentAddress(blob)
+	if err := h.Backend.SetCode(addr, blob); err != nil {
+		fmt.Fprintf(os.Stderr, "athcon: state backend error in Spawn: %v\n", err)
+	}
+	return addr
+}
+
+func (h *StateHostContext) Deploy(code []byte) Address {
+	addr := contentAddress(code)
+	if err := h.Backend.SetCode(addr, code); err != nil {
+		fmt.Fprintf(os.Stderr, "athcon: state backend error in Deploy: %v\n", err)
+	}
+	return addr
+}
+
+func (h *StateHostContext) EmitLog(addr Address, data []byte, topics []Bytes32) {}
+
+// contentAddress derives a program's address from its code, rather than
+// picking one at random, so that two nodes executing the same transactions
+// against the same StateBackend end up with the same addresses and,
+// therefore, the same state root.
+func contentAddress(code []byte) Address {
+	var addr Address
+	hasher := blake3.New()
+	hasher.Write(code)
+	hasher.Digest().Read(addr[:])
+	return addr
+}
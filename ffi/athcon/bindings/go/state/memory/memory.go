@@ -0,0 +1,141 @@
+// Package memory provides an in-memory athcon.StateBackend, suitable for
+// tests and local development.
+package memory
+
+import (
+	athcon "github.com/athenavm/athena/ffi/athcon/bindings/go"
+)
+
+// Backend is an in-memory StateBackend. It has no verifiable state root;
+// callers that need one should use the state/trie backend instead.
+//
+// Snapshot/RevertToSnapshot are backed by a journal of per-key undo
+// closures rather than a deep copy of the backend's maps, so taking a
+// snapshot is O(1) and reverting one is O(changes since that snapshot)
+// rather than O(size of the whole backend).
+type Backend struct {
+	balances map[athcon.Address]uint64
+	storage  map[athcon.Address]map[athcon.Bytes32]athcon.Bytes32
+	code     map[athcon.Address][]byte
+	touched  map[athcon.Address]bool
+
+	journal []func()
+}
+
+// New returns an empty in-memory StateBackend.
+func New() *Backend {
+	return &Backend{
+		balances: make(map[athcon.Address]uint64),
+		storage:  make(map[athcon.Address]map[athcon.Bytes32]athcon.Bytes32),
+		code:     make(map[athcon.Address][]byte),
+		touched:  make(map[athcon.Address]bool),
+	}
+}
+
+// record appends undo to the journal, to be run if the snapshot taken
+// before this change is later rolled back.
+func (b *Backend) record(undo func()) {
+	b.journal = append(b.journal, undo)
+}
+
+// touch marks addr as existing, journaling the change the first time addr
+// is touched so a revert can make it non-existent again.
+func (b *Backend) touch(addr athcon.Address) {
+	if b.touched[addr] {
+		return
+	}
+	b.touched[addr] = true
+	b.record(func() { delete(b.touched, addr) })
+}
+
+func (b *Backend) GetBalance(addr athcon.Address) (uint64, error) {
+	return b.balances[addr], nil
+}
+
+func (b *Backend) SetBalance(addr athcon.Address, balance uint64) error {
+	prev, had := b.balances[addr]
+	b.record(func() {
+		if had {
+			b.balances[addr] = prev
+		} else {
+			delete(b.balances, addr)
+		}
+	})
+	b.balances[addr] = balance
+	b.touch(addr)
+	return nil
+}
+
+func (b *Backend) GetStorage(addr athcon.Address, key athcon.Bytes32) (athcon.Bytes32, error) {
+	return b.storage[addr][key], nil
+}
+
+func (b *Backend) SetStorage(addr athcon.Address, key, value athcon.Bytes32) (athcon.StorageStatus, error) {
+	prev, had := b.storage[addr][key]
+	b.record(func() {
+		if had {
+			b.storage[addr][key] = prev
+		} else {
+			delete(b.storage[addr], key)
+		}
+	})
+	if b.storage[addr] == nil {
+		b.storage[addr] = make(map[athcon.Bytes32]athcon.Bytes32)
+	}
+	b.storage[addr][key] = value
+	b.touch(addr)
+	return storageStatus(prev, value), nil
+}
+
+func storageStatus(prev, next athcon.Bytes32) athcon.StorageStatus {
+	switch {
+	case prev == next:
+		return athcon.StorageAssigned
+	case prev == (athcon.Bytes32{}):
+		return athcon.StorageAdded
+	case next == (athcon.Bytes32{}):
+		return athcon.StorageDeleted
+	default:
+		return athcon.StorageModified
+	}
+}
+
+func (b *Backend) GetCode(addr athcon.Address) ([]byte, error) {
+	return b.code[addr], nil
+}
+
+func (b *Backend) SetCode(addr athcon.Address, code []byte) error {
+	prev, had := b.code[addr]
+	b.record(func() {
+		if had {
+			b.code[addr] = prev
+		} else {
+			delete(b.code, addr)
+		}
+	})
+	b.code[addr] = code
+	b.touch(addr)
+	return nil
+}
+
+func (b *Backend) Exists(addr athcon.Address) (bool, error) {
+	return b.touched[addr], nil
+}
+
+// Snapshot returns an id identifying the current point in the journal, to
+// later be passed to RevertToSnapshot.
+func (b *Backend) Snapshot() int {
+	return len(b.journal)
+}
+
+// RevertToSnapshot undoes every change recorded since id, most recent first.
+func (b *Backend) RevertToSnapshot(id int) {
+	for i := len(b.journal) - 1; i >= id; i-- {
+		b.journal[i]()
+	}
+	b.journal = b.journal[:id]
+}
+
+func (b *Backend) Commit() (athcon.Bytes32, error) {
+	return athcon.Bytes32{}, nil
+}
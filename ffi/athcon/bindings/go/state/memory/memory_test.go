@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"testing"
+
+	athcon "github.com/athenavm/athena/ffi/athcon/bindings/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackendSetGetRoundTrip(t *testing.T) {
+	b := New()
+	addr := athcon.Address{1}
+	key := athcon.Bytes32{2}
+
+	require.NoError(t, b.SetBalance(addr, 100))
+	require.NoError(t, b.SetCode(addr, []byte("code")))
+	_, err := b.SetStorage(addr, key, athcon.Bytes32{3})
+	require.NoError(t, err)
+
+	balance, err := b.GetBalance(addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, balance)
+
+	code, err := b.GetCode(addr)
+	require.NoError(t, err)
+	require.Equal(t, []byte("code"), code)
+
+	value, err := b.GetStorage(addr, key)
+	require.NoError(t, err)
+	require.Equal(t, athcon.Bytes32{3}, value)
+
+	exists, err := b.Exists(addr)
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestBackendExistsFalseUntouched(t *testing.T) {
+	b := New()
+	exists, err := b.Exists(athcon.Address{9})
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestBackendSetStorageStatus(t *testing.T) {
+	b := New()
+	addr := athcon.Address{1}
+	key := athcon.Bytes32{2}
+
+	status, err := b.SetStorage(addr, key, athcon.Bytes32{3})
+	require.NoError(t, err)
+	require.Equal(t, athcon.StorageAdded, status)
+
+	status, err = b.SetStorage(addr, key, athcon.Bytes32{3})
+	require.NoError(t, err)
+	require.Equal(t, athcon.StorageAssigned, status)
+
+	status, err = b.SetStorage(addr, key, athcon.Bytes32{4})
+	require.NoError(t, err)
+	require.Equal(t, athcon.StorageModified, status)
+
+	status, err = b.SetStorage(addr, key, athcon.Bytes32{})
+	require.NoError(t, err)
+	require.Equal(t, athcon.StorageDeleted, status)
+}
+
+func TestBackendRevertToSnapshot(t *testing.T) {
+	b := New()
+	addr := athcon.Address{1}
+	key := athcon.Bytes32{2}
+	require.NoError(t, b.SetBalance(addr, 100))
+
+	snap := b.Snapshot()
+	require.NoError(t, b.SetBalance(addr, 200))
+	_, err := b.SetStorage(addr, key, athcon.Bytes32{3})
+	require.NoError(t, err)
+
+	b.RevertToSnapshot(snap)
+
+	balance, err := b.GetBalance(addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, balance)
+
+	value, err := b.GetStorage(addr, key)
+	require.NoError(t, err)
+	require.Equal(t, athcon.Bytes32{}, value)
+}
+
+func TestBackendRevertToSnapshotUndoesFirstTouch(t *testing.T) {
+	b := New()
+	addr := athcon.Address{1}
+
+	snap := b.Snapshot()
+	require.NoError(t, b.SetBalance(addr, 100))
+	b.RevertToSnapshot(snap)
+
+	exists, err := b.Exists(addr)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestBackendCommitReturnsZeroRoot(t *testing.T) {
+	b := New()
+	require.NoError(t, b.SetBalance(athcon.Address{1}, 100))
+	root, err := b.Commit()
+	require.NoError(t, err)
+	require.Equal(t, athcon.Bytes32{}, root)
+}
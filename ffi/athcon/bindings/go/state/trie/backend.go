@@ -0,0 +1,126 @@
+// Package trie provides a Merkle-Patricia-trie-backed athcon.StateBackend,
+// producing a content-addressed state root that can be shared with, and
+// independently recomputed by, other nodes.
+package trie
+
+import (
+	"encoding/binary"
+
+	athcon "github.com/athenavm/athena/ffi/athcon/bindings/go"
+)
+
+// Backend is a trie-backed StateBackend. Trie nodes are immutable and
+// updates are applied via structural sharing, so prior roots stay valid:
+// Snapshot and RevertToSnapshot only need to record and restore an index
+// into the root history, rather than deep-copying any state.
+type Backend struct {
+	roots []node
+}
+
+// New returns an empty trie-backed StateBackend.
+func New() *Backend {
+	return &Backend{roots: []node{nil}}
+}
+
+func (b *Backend) root() node {
+	return b.roots[len(b.roots)-1]
+}
+
+func (b *Backend) set(key, value []byte) {
+	b.roots = append(b.roots, insert(b.root(), keyToNibbles(key), value))
+}
+
+func balanceKey(addr athcon.Address) []byte { return append([]byte{'b'}, addr[:]...) }
+func codeKey(addr athcon.Address) []byte    { return append([]byte{'c'}, addr[:]...) }
+func existsKey(addr athcon.Address) []byte  { return append([]byte{'e'}, addr[:]...) }
+
+func storageKey(addr athcon.Address, key athcon.Bytes32) []byte {
+	out := make([]byte, 0, 1+len(addr)+len(key))
+	out = append(out, 's')
+	out = append(out, addr[:]...)
+	out = append(out, key[:]...)
+	return out
+}
+
+func (b *Backend) GetBalance(addr athcon.Address) (uint64, error) {
+	v, ok := get(b.root(), keyToNibbles(balanceKey(addr)))
+	if !ok {
+		return 0, nil
+	}
+	return binary.LittleEndian.Uint64(v), nil
+}
+
+func (b *Backend) SetBalance(addr athcon.Address, balance uint64) error {
+	v := make([]byte, 8)
+	binary.LittleEndian.PutUint64(v, balance)
+	b.set(balanceKey(addr), v)
+	b.set(existsKey(addr), []byte{1})
+	return nil
+}
+
+func (b *Backend) GetStorage(addr athcon.Address, key athcon.Bytes32) (athcon.Bytes32, error) {
+	v, ok := get(b.root(), keyToNibbles(storageKey(addr, key)))
+	if !ok {
+		return athcon.Bytes32{}, nil
+	}
+	return athcon.Bytes32(v), nil
+}
+
+func (b *Backend) SetStorage(addr athcon.Address, key, value athcon.Bytes32) (athcon.StorageStatus, error) {
+	prev, err := b.GetStorage(addr, key)
+	if err != nil {
+		return 0, err
+	}
+	b.set(storageKey(addr, key), value[:])
+	b.set(existsKey(addr), []byte{1})
+	return storageStatus(prev, value), nil
+}
+
+func storageStatus(prev, next athcon.Bytes32) athcon.StorageStatus {
+	switch {
+	case prev == next:
+		return athcon.StorageAssigned
+	case prev == (athcon.Bytes32{}):
+		return athcon.StorageAdded
+	case next == (athcon.Bytes32{}):
+		return athcon.StorageDeleted
+	default:
+		return athcon.StorageModified
+	}
+}
+
+func (b *Backend) GetCode(addr athcon.Address) ([]byte, error) {
+	v, ok := get(b.root(), keyToNibbles(codeKey(addr)))
+	if !ok {
+		return nil, nil
+	}
+	return v, nil
+}
+
+func (b *Backend) SetCode(addr athcon.Address, code []byte) error {
+	b.set(codeKey(addr), code)
+	b.set(existsKey(addr), []byte{1})
+	return nil
+}
+
+func (b *Backend) Exists(addr athcon.Address) (bool, error) {
+	_, ok := get(b.root(), keyToNibbles(existsKey(addr)))
+	return ok, nil
+}
+
+// Snapshot returns an id identifying the current root, to later be passed to
+// RevertToSnapshot.
+func (b *Backend) Snapshot() int {
+	return len(b.roots) - 1
+}
+
+// RevertToSnapshot discards every root recorded after id, making id's root
+// current again.
+func (b *Backend) RevertToSnapshot(id int) {
+	b.roots = b.roots[:id+1]
+}
+
+// Commit returns the state root of the current trie.
+func (b *Backend) Commit() (athcon.Bytes32, error) {
+	return athcon.Bytes32(nodeHash(b.root())), nil
+}
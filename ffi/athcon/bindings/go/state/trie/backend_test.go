@@ -0,0 +1,82 @@
+package trie
+
+import (
+	"testing"
+
+	athcon "github.com/athenavm/athena/ffi/athcon/bindings/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackendSetGetRoundTrip(t *testing.T) {
+	b := New()
+	addr := athcon.Address{1}
+	key := athcon.Bytes32{2}
+
+	require.NoError(t, b.SetBalance(addr, 100))
+	require.NoError(t, b.SetCode(addr, []byte("code")))
+	_, err := b.SetStorage(addr, key, athcon.Bytes32{3})
+	require.NoError(t, err)
+
+	balance, err := b.GetBalance(addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, balance)
+
+	code, err := b.GetCode(addr)
+	require.NoError(t, err)
+	require.Equal(t, []byte("code"), code)
+
+	value, err := b.GetStorage(addr, key)
+	require.NoError(t, err)
+	require.Equal(t, athcon.Bytes32{3}, value)
+
+	exists, err := b.Exists(addr)
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestBackendExistsFalseUntouched(t *testing.T) {
+	b := New()
+	exists, err := b.Exists(athcon.Address{9})
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestBackendRevertToSnapshot(t *testing.T) {
+	b := New()
+	addr := athcon.Address{1}
+	require.NoError(t, b.SetBalance(addr, 100))
+
+	snap := b.Snapshot()
+	require.NoError(t, b.SetBalance(addr, 200))
+
+	b.RevertToSnapshot(snap)
+
+	balance, err := b.GetBalance(addr)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, balance)
+}
+
+func TestBackendCommitIsDeterministicAndSensitiveToState(t *testing.T) {
+	a := New()
+	require.NoError(t, a.SetBalance(athcon.Address{1}, 100))
+	rootA, err := a.Commit()
+	require.NoError(t, err)
+
+	b := New()
+	require.NoError(t, b.SetBalance(athcon.Address{1}, 100))
+	rootB, err := b.Commit()
+	require.NoError(t, err)
+	require.Equal(t, rootA, rootB)
+
+	require.NoError(t, b.SetBalance(athcon.Address{1}, 200))
+	rootC, err := b.Commit()
+	require.NoError(t, err)
+	require.NotEqual(t, rootA, rootC)
+}
+
+func TestBackendCommitEmptyIsZeroRoot(t *testing.T) {
+	b := New()
+	root, err := b.Commit()
+	require.NoError(t, err)
+	require.Equal(t, athcon.Bytes32{}, root)
+}
@@ -0,0 +1,40 @@
+package trie
+
+import (
+	"encoding/binary"
+
+	"github.com/zeebo/blake3"
+)
+
+type hasher struct {
+	h *blake3.Hasher
+}
+
+func newHasher() hasher {
+	return hasher{h: blake3.New()}
+}
+
+func (h hasher) Write(p []byte) {
+	h.h.Write(p)
+}
+
+func (h hasher) Sum32() [32]byte {
+	var out [32]byte
+	h.h.Digest().Read(out[:])
+	return out
+}
+
+// hashParts hashes tag followed by each part, length-prefixing every part so
+// that e.g. leafNode's (key, value) pair can't collide with a different
+// (key, value) pair whose concatenation happens to match.
+func hashParts(tag byte, parts ...[]byte) [32]byte {
+	h := newHasher()
+	h.Write([]byte{tag})
+	var length [8]byte
+	for _, p := range parts {
+		binary.LittleEndian.PutUint64(length[:], uint64(len(p)))
+		h.Write(length[:])
+		h.Write(p)
+	}
+	return h.Sum32()
+}
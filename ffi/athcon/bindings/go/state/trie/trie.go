@@ -0,0 +1,158 @@
+package trie
+
+import "bytes"
+
+// node is implemented by the three node kinds of the Merkle-Patricia trie:
+// leaf, extension and branch. A nil node represents the empty trie/subtrie.
+type node interface {
+	hash() [32]byte
+}
+
+type leafNode struct {
+	key   []byte // remaining nibbles
+	value []byte
+}
+
+type extensionNode struct {
+	key   []byte // shared nibbles
+	child node
+}
+
+type branchNode struct {
+	children [16]node
+	value    []byte
+}
+
+func nodeHash(n node) [32]byte {
+	if n == nil {
+		return [32]byte{}
+	}
+	return n.hash()
+}
+
+func (l *leafNode) hash() [32]byte {
+	return hashParts(0x00, l.key, l.value)
+}
+
+func (e *extensionNode) hash() [32]byte {
+	childHash := nodeHash(e.child)
+	return hashParts(0x01, e.key, childHash[:])
+}
+
+func (b *branchNode) hash() [32]byte {
+	h := newHasher()
+	h.Write([]byte{0x02})
+	for _, c := range b.children {
+		childHash := nodeHash(c)
+		h.Write(childHash[:])
+	}
+	h.Write(b.value)
+	return h.Sum32()
+}
+
+// keyToNibbles splits key into its 4-bit nibbles, high nibble first.
+func keyToNibbles(key []byte) []byte {
+	nibbles := make([]byte, len(key)*2)
+	for i, b := range key {
+		nibbles[i*2] = b >> 4
+		nibbles[i*2+1] = b & 0x0f
+	}
+	return nibbles
+}
+
+func commonPrefixLen(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// insert returns the root of the trie obtained by setting key to value in n,
+// leaving n itself untouched so that previously recorded roots remain valid.
+func insert(n node, key []byte, value []byte) node {
+	switch t := n.(type) {
+	case nil:
+		return &leafNode{key: append([]byte(nil), key...), value: value}
+
+	case *leafNode:
+		common := commonPrefixLen(t.key, key)
+		if common == len(t.key) && common == len(key) {
+			return &leafNode{key: t.key, value: value}
+		}
+
+		branch := &branchNode{}
+		if common < len(t.key) {
+			branch.children[t.key[common]] = &leafNode{key: t.key[common+1:], value: t.value}
+		} else {
+			branch.value = t.value
+		}
+		if common < len(key) {
+			branch.children[key[common]] = &leafNode{key: key[common+1:], value: value}
+		} else {
+			branch.value = value
+		}
+		if common == 0 {
+			return branch
+		}
+		return &extensionNode{key: key[:common], child: branch}
+
+	case *extensionNode:
+		common := commonPrefixLen(t.key, key)
+		if common == len(t.key) {
+			return &extensionNode{key: t.key, child: insert(t.child, key[common:], value)}
+		}
+
+		branch := &branchNode{}
+		var oldBranch node = t.child
+		if common+1 < len(t.key) {
+			oldBranch = &extensionNode{key: t.key[common+1:], child: t.child}
+		}
+		branch.children[t.key[common]] = oldBranch
+		if common < len(key) {
+			branch.children[key[common]] = &leafNode{key: key[common+1:], value: value}
+		} else {
+			branch.value = value
+		}
+		if common == 0 {
+			return branch
+		}
+		return &extensionNode{key: key[:common], child: branch}
+
+	case *branchNode:
+		next := *t
+		if len(key) == 0 {
+			next.value = value
+			return &next
+		}
+		next.children[key[0]] = insert(t.children[key[0]], key[1:], value)
+		return &next
+
+	default:
+		panic("trie: unknown node type")
+	}
+}
+
+func get(n node, key []byte) ([]byte, bool) {
+	switch t := n.(type) {
+	case nil:
+		return nil, false
+	case *leafNode:
+		if bytes.Equal(t.key, key) {
+			return t.value, true
+		}
+		return nil, false
+	case *extensionNode:
+		if len(key) < len(t.key) || !bytes.Equal(t.key, key[:len(t.key)]) {
+			return nil, false
+		}
+		return get(t.child, key[len(t.key):])
+	case *branchNode:
+		if len(key) == 0 {
+			return t.value, t.value != nil
+		}
+		return get(t.children[key[0]], key[1:])
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,99 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertGetRoundTrip(t *testing.T) {
+	var root node
+	root = insert(root, keyToNibbles([]byte("alpha")), []byte("1"))
+	root = insert(root, keyToNibbles([]byte("alphabet")), []byte("2"))
+	root = insert(root, keyToNibbles([]byte("beta")), []byte("3"))
+
+	v, ok := get(root, keyToNibbles([]byte("alpha")))
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), v)
+
+	v, ok = get(root, keyToNibbles([]byte("alphabet")))
+	require.True(t, ok)
+	require.Equal(t, []byte("2"), v)
+
+	v, ok = get(root, keyToNibbles([]byte("beta")))
+	require.True(t, ok)
+	require.Equal(t, []byte("3"), v)
+}
+
+func TestGetMissingKey(t *testing.T) {
+	var root node
+	root = insert(root, keyToNibbles([]byte("alpha")), []byte("1"))
+
+	_, ok := get(root, keyToNibbles([]byte("missing")))
+	require.False(t, ok)
+}
+
+func TestInsertOverwritesExistingKey(t *testing.T) {
+	var root node
+	root = insert(root, keyToNibbles([]byte("alpha")), []byte("1"))
+	root = insert(root, keyToNibbles([]byte("alpha")), []byte("2"))
+
+	v, ok := get(root, keyToNibbles([]byte("alpha")))
+	require.True(t, ok)
+	require.Equal(t, []byte("2"), v)
+}
+
+func TestInsertLeavesPriorRootUntouched(t *testing.T) {
+	var before node
+	before = insert(before, keyToNibbles([]byte("alpha")), []byte("1"))
+	after := insert(before, keyToNibbles([]byte("alpha")), []byte("2"))
+
+	v, ok := get(before, keyToNibbles([]byte("alpha")))
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), v)
+
+	v, ok = get(after, keyToNibbles([]byte("alpha")))
+	require.True(t, ok)
+	require.Equal(t, []byte("2"), v)
+}
+
+func TestHashDeterministic(t *testing.T) {
+	var a, b node
+	a = insert(a, keyToNibbles([]byte("alpha")), []byte("1"))
+	a = insert(a, keyToNibbles([]byte("beta")), []byte("2"))
+	b = insert(b, keyToNibbles([]byte("alpha")), []byte("1"))
+	b = insert(b, keyToNibbles([]byte("beta")), []byte("2"))
+
+	require.Equal(t, nodeHash(a), nodeHash(b))
+}
+
+func TestHashSensitiveToValue(t *testing.T) {
+	var a, b node
+	a = insert(a, keyToNibbles([]byte("alpha")), []byte("1"))
+	b = insert(b, keyToNibbles([]byte("alpha")), []byte("2"))
+
+	require.NotEqual(t, nodeHash(a), nodeHash(b))
+}
+
+func TestHashSensitiveToKey(t *testing.T) {
+	var a, b node
+	a = insert(a, keyToNibbles([]byte("alpha")), []byte("1"))
+	b = insert(b, keyToNibbles([]byte("alphb")), []byte("1"))
+
+	require.NotEqual(t, nodeHash(a), nodeHash(b))
+}
+
+func TestNodeHashNilIsZero(t *testing.T) {
+	require.Equal(t, [32]byte{}, nodeHash(nil))
+}
+
+// TestLeafHashUnambiguousAtKeyValueBoundary checks that a leaf's hash binds
+// the exact (key, value) split, not just the concatenation of the two: two
+// leaves whose key+value bytes coincide but split differently must hash
+// differently.
+func TestLeafHashUnambiguousAtKeyValueBoundary(t *testing.T) {
+	a := &leafNode{key: []byte{1, 2}, value: []byte{3}}
+	b := &leafNode{key: []byte{1}, value: []byte{2, 3}}
+
+	require.NotEqual(t, a.hash(), b.hash())
+}
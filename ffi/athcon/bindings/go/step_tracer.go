@@ -0,0 +1,78 @@
+package athcon
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// StepTracer observes per-instruction VM execution, in the shape EIP-3155
+// struct logs use, so a trace can be diffed against other EVM-style VMs.
+//
+// Unlike Tracer, which only sees the host-call boundary this Go package
+// owns, wiring StepTracer up end to end needs a callback vtable threaded
+// across the FFI boundary into vmlib, plus a hook point in its executor
+// loop -- native code that is not part of this bindings-only checkout.
+// StepTracer and JSONStepTracer are defined here as the Go-side contract a
+// future LoadAndConfigure option can deliver CaptureState events into;
+// nothing in this package invokes them yet.
+type StepTracer interface {
+	CaptureStart(caller, callee Address, input []byte, gas uint64, value Bytes32)
+	CaptureState(pc uint64, op uint8, gasLeft, gasCost uint64, depth int, err error)
+	CaptureFault(pc uint64, op uint8, gasLeft uint64, depth int, err error)
+	CaptureEnd(output []byte, gasUsed uint64, err error)
+}
+
+// StructLog is one EIP-3155-shaped entry describing a single instruction.
+// Stack, Memory, and Storage are the optional fields of EIP-3155 and are
+// omitted from the JSON when empty; JSONStepTracer never sets them.
+type StructLog struct {
+	Pc      uint64            `json:"pc"`
+	Op      uint8             `json:"op"`
+	Gas     uint64            `json:"gas"`
+	GasCost uint64            `json:"gasCost"`
+	Depth   int               `json:"depth"`
+	Err     string            `json:"error,omitempty"`
+	Stack   []string          `json:"stack,omitempty"`
+	Memory  string            `json:"memory,omitempty"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+// JSONStepTracer is a StepTracer that writes one StructLog per
+// CaptureState/CaptureFault call to an io.Writer as newline-delimited JSON,
+// matching EIP-3155 so the trace can be diffed against other VMs' struct
+// logs.
+type JSONStepTracer struct {
+	w io.Writer
+}
+
+// NewJSONStepTracer returns a JSONStepTracer that writes newline-delimited
+// StructLog JSON objects to w.
+func NewJSONStepTracer(w io.Writer) *JSONStepTracer {
+	return &JSONStepTracer{w: w}
+}
+
+func (t *JSONStepTracer) CaptureStart(caller, callee Address, input []byte, gas uint64, value Bytes32) {
+}
+
+func (t *JSONStepTracer) CaptureState(pc uint64, op uint8, gasLeft, gasCost uint64, depth int, err error) {
+	log := StructLog{Pc: pc, Op: op, Gas: gasLeft, GasCost: gasCost, Depth: depth}
+	if err != nil {
+		log.Err = err.Error()
+	}
+	t.write(log)
+}
+
+func (t *JSONStepTracer) CaptureFault(pc uint64, op uint8, gasLeft uint64, depth int, err error) {
+	t.CaptureState(pc, op, gasLeft, 0, depth, err)
+}
+
+func (t *JSONStepTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (t *JSONStepTracer) write(log StructLog) {
+	line, err := json.Marshal(log)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	t.w.Write(line)
+}
@@ -0,0 +1,23 @@
+package athcon
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONStepTracerCaptureState(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewJSONStepTracer(&buf)
+
+	tracer.CaptureState(1, 0x01, 100, 3, 2, nil)
+	tracer.CaptureFault(2, 0x02, 97, 2, errors.New("boom"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.JSONEq(t, `{"pc":1,"op":1,"gas":100,"gasCost":3,"depth":2}`, lines[0])
+	require.Contains(t, lines[1], `"error":"boom"`)
+}
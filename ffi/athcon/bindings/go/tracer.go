@@ -0,0 +1,317 @@
+package athcon
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+// Tracer observes the host-call boundary of a VM.Execute: it is notified
+// before and after every nested Call a HostContext dispatches, and whenever
+// the default host touches storage, balance, spawn/deploy, or emits a log.
+// It is the same shape as the EVM `tracers` package most Ethereum forks
+// expose, and lets callers debug Athena VM programs without re-running
+// under a native debugger.
+//
+// A Tracer only sees calls that cross the boundary ExecuteWithTracer wraps:
+// if a HostContext.Call implementation recurses into vm.Execute with its
+// own concrete HostContext rather than the traced one it was given (as
+// testHostContext does), that nested execution's host calls are invisible
+// to the tracer.
+type Tracer interface {
+	// OnEnter fires when a Call is about to be dispatched, before control
+	// reaches the HostContext.
+	OnEnter(depth int, kind CallKind, recipient, sender Address, input []byte, gas int64, value uint64)
+	// OnExit fires once a dispatched Call returns.
+	OnExit(depth int, output []byte, gasLeft int64, err error)
+	// OnSyscall fires for the HostContext methods AccountExists,
+	// GetStorage, GetBalance, GetBlockHash, Spawn, and Deploy;
+	// GetTxContext is not reported. args and
+	// result are a best-effort raw encoding of that method's parameters and
+	// return value, intended for display rather than decoding.
+	OnSyscall(name string, args, result []byte, gasBefore, gasAfter int64)
+	// OnStorageChange fires for every SetStorage call, before the status it
+	// returns is known.
+	OnStorageChange(addr Address, key, prev, value Bytes32)
+	// OnLog fires for every EmitLog call.
+	OnLog(log Log)
+}
+
+// tracingHostContext wraps a HostContext, notifying a Tracer around each
+// method before delegating to it. ExecuteWithTracer passes one of these to
+// VM.Execute in place of the caller's HostContext.
+//
+// gas is the gas the enclosing Call frame was given; individual host
+// methods other than Call carry no gas cost of their own at this layer, so
+// OnSyscall reports it unchanged as both gasBefore and gasAfter.
+type tracingHostContext struct {
+	ctx    HostContext
+	tracer Tracer
+	gas    int64
+}
+
+func (t *tracingHostContext) AccountExists(addr Address) bool {
+	exists := t.ctx.AccountExists(addr)
+	t.tracer.OnSyscall("AccountExists", addr[:], boolBytes(exists), t.gas, t.gas)
+	return exists
+}
+
+func (t *tracingHostContext) GetStorage(addr Address, key Bytes32) (Bytes32, error) {
+	value, err := t.ctx.GetStorage(addr, key)
+	t.tracer.OnSyscall("GetStorage", append(addr[:], key[:]...), value[:], t.gas, t.gas)
+	return value, err
+}
+
+func (t *tracingHostContext) SetStorage(addr Address, key, value Bytes32) (StorageStatus, error) {
+	prev, _ := t.ctx.GetStorage(addr, key)
+	t.tracer.OnStorageChange(addr, key, prev, value)
+	return t.ctx.SetStorage(addr, key, value)
+}
+
+func (t *tracingHostContext) GetBalance(addr Address) (uint64, error) {
+	balance, err := t.ctx.GetBalance(addr)
+	t.tracer.OnSyscall("GetBalance", addr[:], uint64Bytes(balance), t.gas, t.gas)
+	return balance, err
+}
+
+func (t *tracingHostContext) GetTxContext() TxContext {
+	return t.ctx.GetTxContext()
+}
+
+func (t *tracingHostContext) GetBlockHash(number int64) (Bytes32, error) {
+	hash, err := t.ctx.GetBlockHash(number)
+	t.tracer.OnSyscall("GetBlockHash", int64Bytes(number), hash[:], t.gas, t.gas)
+	return hash, err
+}
+
+func (t *tracingHostContext) Call(kind CallKind, recipient, sender Address, value uint64, input []byte, gas int64, depth int) (
+	output []byte, gasLeft int64, err error) {
+	t.tracer.OnEnter(depth, kind, recipient, sender, input, gas, value)
+	output, gasLeft, err = t.ctx.Call(kind, recipient, sender, value, input, gas, depth)
+	t.tracer.OnExit(depth, output, gasLeft, err)
+	return output, gasLeft, err
+}
+
+func (t *tracingHostContext) Spawn(blob []byte) Address {
+	addr := t.ctx.Spawn(blob)
+	t.tracer.OnSyscall("Spawn", blob, addr[:], t.gas, t.gas)
+	return addr
+}
+
+func (t *tracingHostContext) Deploy(code []byte) Address {
+	addr := t.ctx.Deploy(code)
+	t.tracer.OnSyscall("Deploy", code, addr[:], t.gas, t.gas)
+	return addr
+}
+
+func (t *tracingHostContext) EmitLog(addr Address, data []byte, topics []Bytes32) {
+	t.ctx.EmitLog(addr, data, topics)
+	t.tracer.OnLog(Log{Address: addr, Data: data, Topics: topics})
+}
+
+// snapshottingTracingHostContext adds Snapshotter to a tracingHostContext
+// whose wrapped ctx supports it, so that tracing a HostContext never
+// disables the nested-call atomicity the call bridge provides through
+// Snapshotter.
+type snapshottingTracingHostContext struct {
+	*tracingHostContext
+	snap Snapshotter
+}
+
+func (t *snapshottingTracingHostContext) Snapshot() int {
+	return t.snap.Snapshot()
+}
+
+func (t *snapshottingTracingHostContext) RevertToSnapshot(id int) {
+	t.snap.RevertToSnapshot(id)
+}
+
+func boolBytes(b bool) []byte {
+	if b {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func uint64Bytes(v uint64) []byte {
+	out := make([]byte, 8)
+	binary.LittleEndian.PutUint64(out, v)
+	return out
+}
+
+func int64Bytes(v int64) []byte {
+	return uint64Bytes(uint64(v))
+}
+
+// ExecuteWithTracer behaves exactly like Execute, except that every host
+// call VM.Execute dispatches through ctx is also reported to tracer.
+func (vm *VM) ExecuteWithTracer(
+	ctx HostContext,
+	tracer Tracer,
+	rev Revision,
+	kind CallKind,
+	depth int,
+	gas int64,
+	recipient, sender Address,
+	input []byte,
+	value uint64,
+	code []byte,
+) (Result, error) {
+	traced := &tracingHostContext{ctx: ctx, tracer: tracer, gas: gas}
+	var wrapped HostContext = traced
+	if snap, ok := ctx.(Snapshotter); ok {
+		wrapped = &snapshottingTracingHostContext{tracingHostContext: traced, snap: snap}
+	}
+
+	tracer.OnEnter(depth, kind, recipient, sender, input, gas, value)
+	res, err := vm.Execute(wrapped, rev, kind, depth, gas, recipient, sender, input, value, code)
+	tracer.OnExit(depth, res.Output, res.GasLeft, err)
+	return res, err
+}
+
+// JSONTracer is a Tracer that writes one JSON object per event to an
+// io.Writer. Like any Tracer, it sees only the host calls that pass through
+// the HostContext given to ExecuteWithTracer; in TestCall's recursion, for
+// example, only the Calls made directly by the top-level execution are
+// recorded, not those made from inside them.
+type JSONTracer struct {
+	w io.Writer
+}
+
+// NewJSONTracer returns a JSONTracer that writes newline-delimited JSON
+// objects to w.
+func NewJSONTracer(w io.Writer) *JSONTracer {
+	return &JSONTracer{w: w}
+}
+
+func (t *JSONTracer) emit(event string, fields map[string]any) {
+	fields["event"] = event
+	line, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	t.w.Write(line)
+}
+
+func (t *JSONTracer) OnEnter(depth int, kind CallKind, recipient, sender Address, input []byte, gas int64, value uint64) {
+	t.emit("enter", map[string]any{
+		"depth":     depth,
+		"kind":      kind,
+		"recipient": hex.EncodeToString(recipient[:]),
+		"sender":    hex.EncodeToString(sender[:]),
+		"input":     hex.EncodeToString(input),
+		"gas":       gas,
+		"value":     value,
+	})
+}
+
+func (t *JSONTracer) OnExit(depth int, output []byte, gasLeft int64, err error) {
+	fields := map[string]any{
+		"depth":   depth,
+		"output":  hex.EncodeToString(output),
+		"gasLeft": gasLeft,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	t.emit("exit", fields)
+}
+
+func (t *JSONTracer) OnSyscall(name string, args, result []byte, gasBefore, gasAfter int64) {
+	t.emit("syscall", map[string]any{
+		"name":      name,
+		"args":      hex.EncodeToString(args),
+		"result":    hex.EncodeToString(result),
+		"gasBefore": gasBefore,
+		"gasAfter":  gasAfter,
+	})
+}
+
+func (t *JSONTracer) OnStorageChange(addr Address, key, prev, value Bytes32) {
+	t.emit("storageChange", map[string]any{
+		"address": hex.EncodeToString(addr[:]),
+		"key":     hex.EncodeToString(key[:]),
+		"prev":    hex.EncodeToString(prev[:]),
+		"value":   hex.EncodeToString(value[:]),
+	})
+}
+
+func (t *JSONTracer) OnLog(log Log) {
+	topics := make([]string, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = hex.EncodeToString(topic[:])
+	}
+	t.emit("log", map[string]any{
+		"address": hex.EncodeToString(log.Address[:]),
+		"data":    hex.EncodeToString(log.Data),
+		"topics":  topics,
+	})
+}
+
+// CallFrame is one frame of the call tree CallTreeTracer builds: the
+// top-level Execute call, plus one frame per nested Call dispatched from
+// it, each holding its own children in dispatch order.
+type CallFrame struct {
+	Depth     int
+	Kind      CallKind
+	Recipient Address
+	Sender    Address
+	Input     []byte
+	Gas       int64
+	Value     uint64
+
+	Output  []byte
+	GasLeft int64
+	Err     error
+
+	Children []*CallFrame
+}
+
+// CallTreeTracer is a Tracer that reconstructs the nested-call structure of
+// an execution as a *CallFrame tree, rooted at Root once the outermost
+// OnExit has fired.
+type CallTreeTracer struct {
+	Root  *CallFrame
+	stack []*CallFrame
+}
+
+// NewCallTreeTracer returns an empty CallTreeTracer ready to pass to
+// VM.ExecuteWithTracer.
+func NewCallTreeTracer() *CallTreeTracer {
+	return &CallTreeTracer{}
+}
+
+func (t *CallTreeTracer) OnEnter(depth int, kind CallKind, recipient, sender Address, input []byte, gas int64, value uint64) {
+	frame := &CallFrame{
+		Depth:     depth,
+		Kind:      kind,
+		Recipient: recipient,
+		Sender:    sender,
+		Input:     input,
+		Gas:       gas,
+		Value:     value,
+	}
+	if len(t.stack) == 0 {
+		t.Root = frame
+	} else {
+		parent := t.stack[len(t.stack)-1]
+		parent.Children = append(parent.Children, frame)
+	}
+	t.stack = append(t.stack, frame)
+}
+
+func (t *CallTreeTracer) OnExit(depth int, output []byte, gasLeft int64, err error) {
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	frame.Output = output
+	frame.GasLeft = gasLeft
+	frame.Err = err
+}
+
+func (t *CallTreeTracer) OnSyscall(name string, args, result []byte, gasBefore, gasAfter int64) {}
+
+func (t *CallTreeTracer) OnStorageChange(addr Address, key, prev, value Bytes32) {}
+
+func (t *CallTreeTracer) OnLog(log Log) {}
@@ -0,0 +1,56 @@
+package athcon
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestExecuteWithTracerJSON spawns a wallet through ExecuteWithTracer,
+// checking that the JSONTracer output starts with the top-level enter event
+// and ends with its exit event.
+func TestExecuteWithTracerJSON(t *testing.T) {
+	vm, _ := Load(libPath(t))
+	defer vm.Destroy()
+
+	host := newHost(vm)
+	principal := Address{1, 2, 3, 4}
+	pubkey := Bytes32([32]byte{1, 1, 2, 2, 3, 3, 4, 4})
+	executionPayload := EncodedExecutionPayload(nil, vm.Lib.EncodeTxSpawn(pubkey))
+
+	var buf bytes.Buffer
+	tracer := NewJSONTracer(&buf)
+	result, err := vm.ExecuteWithTracer(host, tracer, Frontier, Call, 1, 10000, principal, principal, executionPayload, 0, WALLET_TEST)
+	require.NoError(t, err)
+	require.Len(t, result.Output, 24)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.GreaterOrEqual(t, len(lines), 2)
+	require.Contains(t, lines[0], `"event":"enter"`)
+	require.Contains(t, lines[len(lines)-1], `"event":"exit"`)
+}
+
+// TestExecuteWithTracerCallTree checks that CallTreeTracer records the
+// top-level frame's recipient, sender, and final output.
+func TestExecuteWithTracerCallTree(t *testing.T) {
+	vm, _ := Load(libPath(t))
+	defer vm.Destroy()
+
+	host := newHost(vm)
+	addr := randomAddress()
+	host.balances[addr] = 1000
+
+	tracer := NewCallTreeTracer()
+	result, err := vm.ExecuteWithTracer(host, tracer, Frontier, Call, 1, 100, addr, addr, nil, 0, MINIMAL_TEST_CODE)
+	require.NoError(t, err)
+
+	require.NotNil(t, tracer.Root)
+	require.Equal(t, addr, tracer.Root.Recipient)
+	require.Equal(t, addr, tracer.Root.Sender)
+	require.Equal(t, result.Output, tracer.Root.Output)
+	require.Equal(t, result.GasLeft, tracer.Root.GasLeft)
+	require.NoError(t, tracer.Root.Err)
+	require.Empty(t, tracer.Root.Children)
+}
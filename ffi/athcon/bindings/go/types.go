@@ -30,8 +30,13 @@ func FromString(value string) (MethodSelector, error) {
 	return selector, nil
 }
 
-// String implements the fmt.Stringer interface for MethodSelector, similar to Rust's Display trait.
+// String implements the fmt.Stringer interface for MethodSelector, similar
+// to Rust's Display trait. If ms was Register-ed with the default
+// SelectorRegistry, String shows the resolved method name instead of hex.
 func (ms MethodSelector) String() string {
+	if name, _, ok := Lookup(ms); ok {
+		return name
+	}
 	return hex.EncodeToString(ms[:])
 }
 